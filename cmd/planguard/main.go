@@ -0,0 +1,57 @@
+// Command planguard runs the repo's Rego policy set against a terraform
+// plan outside of `go test`, so CI can gate on it the same way it would on
+// checkov or tfsec. It shares its evaluation logic with Test_PlanChecks via
+// the planchecks package.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/KolaAina/GO-terraform-s3-devops-project/test/planchecks"
+)
+
+func main() {
+	var cfg planchecks.PlanCheckConfig
+	var failOn string
+
+	flag.StringVar(&cfg.PlanPath, "plan", "", "path to a `terraform show -json` plan document (required)")
+	flag.StringVar(&cfg.PoliciesDir, "policies", "policies", "root directory of Rego policies")
+	flag.StringVar(&cfg.ReportFormat, "format", "", "report format: junit, sarif, or empty for none")
+	flag.StringVar(&cfg.OutputPath, "out", "", "path to write the report (required with -format)")
+	flag.StringVar(&failOn, "fail-on", "deny", "minimum severity that fails the run: warn or deny (aliases: low, high)")
+	flag.Parse()
+
+	if cfg.PlanPath == "" {
+		fmt.Fprintln(os.Stderr, "planguard: -plan is required")
+		os.Exit(2)
+	}
+	if cfg.ReportFormat != "" && cfg.OutputPath == "" {
+		fmt.Fprintln(os.Stderr, "planguard: -out is required when -format is set")
+		os.Exit(2)
+	}
+
+	threshold, err := planchecks.ParseSeverity(failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "planguard: %v\n", err)
+		os.Exit(2)
+	}
+
+	result, err := planchecks.RunPlanChecks(context.Background(), cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "planguard: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, v := range result.Violations {
+		fmt.Printf("[%s] %s: %s\n", v.Severity, v.Package, v.Message)
+	}
+
+	failing := result.Failing(threshold)
+	if len(failing) > 0 {
+		fmt.Fprintf(os.Stderr, "planguard: %d violation(s) at or above %q\n", len(failing), failOn)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,139 @@
+package planquery
+
+import "fmt"
+
+// Attrs is a single resolved attribute map (a resource's `after`/`values`,
+// or a nested block pulled out of one via List/At).
+type Attrs struct {
+	raw map[string]interface{}
+	err error
+}
+
+// Err returns the first error encountered building these Attrs.
+func (a *Attrs) Err() error { return a.err }
+
+// Raw returns the raw value at key, with no type assertion.
+func (a *Attrs) Raw(key string) (interface{}, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	v, ok := a.raw[key]
+	if !ok {
+		return nil, fmt.Errorf("planquery: attribute %q not found", key)
+	}
+	return v, nil
+}
+
+// String returns the string at key.
+func (a *Attrs) String(key string) (string, error) {
+	v, err := a.Raw(key)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("planquery: attribute %q is not a string (got %T)", key, v)
+	}
+	return s, nil
+}
+
+// Bool returns the bool at key.
+func (a *Attrs) Bool(key string) (bool, error) {
+	v, err := a.Raw(key)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("planquery: attribute %q is not a bool (got %T)", key, v)
+	}
+	return b, nil
+}
+
+// Float64 returns the number at key (plan JSON decodes all numbers as
+// float64).
+func (a *Attrs) Float64(key string) (float64, error) {
+	v, err := a.Raw(key)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("planquery: attribute %q is not a number (got %T)", key, v)
+	}
+	return f, nil
+}
+
+// StringSlice returns the string array at key.
+func (a *Attrs) StringSlice(key string) ([]string, error) {
+	v, err := a.Raw(key)
+	if err != nil {
+		return nil, err
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("planquery: attribute %q is not an array (got %T)", key, v)
+	}
+	out := make([]string, 0, len(arr))
+	for _, e := range arr {
+		s, ok := e.(string)
+		if !ok {
+			return nil, fmt.Errorf("planquery: attribute %q contains a non-string element (got %T)", key, e)
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// List handles plan JSON's "object OR list-of-objects" shape for block
+// attributes (e.g. versioning_configuration, rule), returning the blocks as
+// a ListAttrs you can index into.
+func (a *Attrs) List(key string) *ListAttrs {
+	if a.err != nil {
+		return &ListAttrs{err: a.err}
+	}
+	v, ok := a.raw[key]
+	if !ok {
+		return &ListAttrs{err: fmt.Errorf("planquery: attribute %q not found", key)}
+	}
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return &ListAttrs{items: []map[string]interface{}{vv}}
+	case []interface{}:
+		items, err := toMapSlice(vv, fmt.Sprintf("attribute %q", key))
+		if err != nil {
+			return &ListAttrs{err: fmt.Errorf("planquery: %w", err)}
+		}
+		return &ListAttrs{items: items}
+	default:
+		return &ListAttrs{err: fmt.Errorf("planquery: attribute %q is not an object or list (got %T)", key, v)}
+	}
+}
+
+// ListAttrs is a list of attribute blocks, as produced by Attrs.List.
+type ListAttrs struct {
+	items []map[string]interface{}
+	err   error
+}
+
+// Err returns the first error encountered building this ListAttrs.
+func (l *ListAttrs) Err() error { return l.err }
+
+// Len returns the number of blocks in the list.
+func (l *ListAttrs) Len() int { return len(l.items) }
+
+// First returns the first block, erroring if the list is empty.
+func (l *ListAttrs) First() *Attrs {
+	return l.At(0)
+}
+
+// At returns the block at index i.
+func (l *ListAttrs) At(i int) *Attrs {
+	if l.err != nil {
+		return &Attrs{err: l.err}
+	}
+	if i < 0 || i >= len(l.items) {
+		return &Attrs{err: fmt.Errorf("planquery: index %d out of range (len %d)", i, len(l.items))}
+	}
+	return &Attrs{raw: l.items[i]}
+}
@@ -0,0 +1,89 @@
+package planquery
+
+import "testing"
+
+func Test_Attrs_List(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     map[string]interface{}
+		key     string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "missing key errors",
+			raw:     map[string]interface{}{},
+			key:     "versioning_configuration",
+			wantErr: true,
+		},
+		{
+			name: "bare object normalizes to a single-element list",
+			raw: map[string]interface{}{
+				"versioning_configuration": map[string]interface{}{"status": "Enabled"},
+			},
+			key:     "versioning_configuration",
+			wantLen: 1,
+		},
+		{
+			name: "array of objects is returned as-is",
+			raw: map[string]interface{}{
+				"rule": []interface{}{
+					map[string]interface{}{"id": "a"},
+					map[string]interface{}{"id": "b"},
+				},
+			},
+			key:     "rule",
+			wantLen: 2,
+		},
+		{
+			name: "non-object, non-array value errors",
+			raw: map[string]interface{}{
+				"bucket": "my-bucket",
+			},
+			key:     "bucket",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			list := (&Attrs{raw: tc.raw}).List(tc.key)
+			if tc.wantErr {
+				if list.Err() == nil {
+					t.Fatalf("List(%q).Err() = nil, want error", tc.key)
+				}
+				return
+			}
+			if list.Err() != nil {
+				t.Fatalf("List(%q).Err() = %v, want nil", tc.key, list.Err())
+			}
+			if list.Len() != tc.wantLen {
+				t.Errorf("List(%q).Len() = %d, want %d", tc.key, list.Len(), tc.wantLen)
+			}
+		})
+	}
+}
+
+func Test_Attrs_List_AtOutOfRange(t *testing.T) {
+	list := (&Attrs{raw: map[string]interface{}{
+		"rule": []interface{}{map[string]interface{}{"id": "a"}},
+	}}).List("rule")
+
+	if err := list.At(5).Err(); err == nil {
+		t.Fatal("At(5).Err() = nil, want out-of-range error")
+	}
+}
+
+func Test_Attrs_TypeMismatchErrors(t *testing.T) {
+	a := &Attrs{raw: map[string]interface{}{"name": "not-a-bool"}}
+
+	if _, err := a.Bool("name"); err == nil {
+		t.Fatal("Bool() on a string attribute returned nil error")
+	}
+	if _, err := a.Float64("name"); err == nil {
+		t.Fatal("Float64() on a string attribute returned nil error")
+	}
+	if _, err := a.StringSlice("name"); err == nil {
+		t.Fatal("StringSlice() on a string attribute returned nil error")
+	}
+}
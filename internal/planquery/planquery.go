@@ -0,0 +1,232 @@
+// Package planquery is a small, fluent query layer over terraform plan
+// JSON (the output of `terraform show -json`). It replaces the ad-hoc
+// asMap/firstMap/getAfter/find helpers the plan tests used to hand-roll:
+// every step propagates errors instead of panicking on a bad type
+// assertion, and a missing attribute surfaces as an error from the
+// terminal call instead of a silent nil.
+//
+// Typical usage:
+//
+//	status, err := planquery.Load(planJSON).
+//		OfType("aws_s3_bucket_versioning").
+//		After().
+//		List("versioning_configuration").
+//		First().
+//		String("status")
+package planquery
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type view int
+
+const (
+	viewResourceChanges view = iota
+	viewPlannedValues
+)
+
+// Plan is a parsed terraform plan document, addressed via resource_changes
+// by default.
+type Plan struct {
+	raw  map[string]interface{}
+	view view
+	err  error
+}
+
+// Load parses plan JSON (the output of `terraform show -json`).
+func Load(data []byte) *Plan {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return &Plan{err: fmt.Errorf("planquery: parse plan: %w", err)}
+	}
+	return &Plan{raw: raw, view: viewResourceChanges}
+}
+
+// Err returns the first error encountered building this Plan, if any.
+func (p *Plan) Err() error { return p.err }
+
+// PlannedValues switches subsequent OfType/ByAddress calls onto
+// planned_values.root_module, which holds fully-resolved attribute values
+// for computed fields resource_changes can't see (e.g. a trust policy JSON
+// that's only known once AWS assigns IDs).
+func (p *Plan) PlannedValues() *Plan {
+	if p.err != nil {
+		return p
+	}
+	return &Plan{raw: p.raw, view: viewPlannedValues}
+}
+
+// OfType returns every resource of the given type in the current view.
+func (p *Plan) OfType(typ string) *ResourceSet {
+	if p.err != nil {
+		return &ResourceSet{err: p.err}
+	}
+	resources, err := p.resources()
+	if err != nil {
+		return &ResourceSet{err: err}
+	}
+	var hits []map[string]interface{}
+	for _, r := range resources {
+		if r["type"] == typ {
+			hits = append(hits, r)
+		}
+	}
+	return &ResourceSet{items: hits, view: p.view, desc: fmt.Sprintf("type %q", typ)}
+}
+
+// ByAddress returns the single resource at the given terraform address.
+func (p *Plan) ByAddress(address string) *Resource {
+	if p.err != nil {
+		return &Resource{err: p.err}
+	}
+	resources, err := p.resources()
+	if err != nil {
+		return &Resource{err: err}
+	}
+	for _, r := range resources {
+		if r["address"] == address {
+			return &Resource{raw: r, view: p.view}
+		}
+	}
+	return &Resource{err: fmt.Errorf("planquery: no resource at address %q", address)}
+}
+
+func (p *Plan) resources() ([]map[string]interface{}, error) {
+	if p.view == viewPlannedValues {
+		return planValuesResources(p.raw)
+	}
+	return resourceChanges(p.raw)
+}
+
+func resourceChanges(raw map[string]interface{}) ([]map[string]interface{}, error) {
+	rcAny, ok := raw["resource_changes"]
+	if !ok {
+		return nil, fmt.Errorf("planquery: plan has no resource_changes")
+	}
+	rc, ok := rcAny.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("planquery: resource_changes is not an array (got %T)", rcAny)
+	}
+	return toMapSlice(rc, "resource_changes")
+}
+
+func planValuesResources(raw map[string]interface{}) ([]map[string]interface{}, error) {
+	root, ok := raw["planned_values"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("planquery: plan has no planned_values")
+	}
+	module, ok := root["root_module"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("planquery: planned_values has no root_module")
+	}
+	resAny, ok := module["resources"]
+	if !ok {
+		return nil, fmt.Errorf("planquery: root_module has no resources")
+	}
+	res, ok := resAny.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("planquery: root_module.resources is not an array (got %T)", resAny)
+	}
+	return toMapSlice(res, "root_module.resources")
+}
+
+func toMapSlice(in []interface{}, desc string) ([]map[string]interface{}, error) {
+	out := make([]map[string]interface{}, 0, len(in))
+	for _, e := range in {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("planquery: %s contains a non-object element (got %T)", desc, e)
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// ResourceSet is a (possibly empty) set of resources matched by a query
+// such as OfType.
+type ResourceSet struct {
+	items []map[string]interface{}
+	view  view
+	desc  string
+	err   error
+}
+
+// Err returns the first error encountered building this ResourceSet.
+func (rs *ResourceSet) Err() error { return rs.err }
+
+// Len returns the number of matched resources.
+func (rs *ResourceSet) Len() int { return len(rs.items) }
+
+// All returns every matched resource.
+func (rs *ResourceSet) All() []*Resource {
+	out := make([]*Resource, 0, len(rs.items))
+	for _, item := range rs.items {
+		out = append(out, &Resource{raw: item, view: rs.view})
+	}
+	return out
+}
+
+// First returns the first matched resource, erroring if the set is empty.
+func (rs *ResourceSet) First() *Resource {
+	if rs.err != nil {
+		return &Resource{err: rs.err}
+	}
+	if len(rs.items) == 0 {
+		return &Resource{err: fmt.Errorf("planquery: no resources matched %s", rs.desc)}
+	}
+	return &Resource{raw: rs.items[0], view: rs.view}
+}
+
+// After is shorthand for First().After().
+func (rs *ResourceSet) After() *Attrs {
+	return rs.First().After()
+}
+
+// Resource is a single matched resource.
+type Resource struct {
+	raw  map[string]interface{}
+	view view
+	err  error
+}
+
+// Err returns the first error encountered building this Resource.
+func (r *Resource) Err() error { return r.err }
+
+// Address returns the resource's terraform address.
+func (r *Resource) Address() (string, error) {
+	if r.err != nil {
+		return "", r.err
+	}
+	addr, ok := r.raw["address"].(string)
+	if !ok {
+		return "", fmt.Errorf("planquery: resource has no address")
+	}
+	return addr, nil
+}
+
+// After returns the resolved attribute set for this resource: change.after
+// for a resource_changes-sourced resource, or values for one sourced from
+// planned_values (which holds only the final value, no before/after).
+func (r *Resource) After() *Attrs {
+	if r.err != nil {
+		return &Attrs{err: r.err}
+	}
+	if r.view == viewPlannedValues {
+		values, ok := r.raw["values"].(map[string]interface{})
+		if !ok {
+			return &Attrs{err: fmt.Errorf("planquery: resource has no values")}
+		}
+		return &Attrs{raw: values}
+	}
+	change, ok := r.raw["change"].(map[string]interface{})
+	if !ok {
+		return &Attrs{err: fmt.Errorf("planquery: resource has no change")}
+	}
+	after, ok := change["after"].(map[string]interface{})
+	if !ok {
+		return &Attrs{err: fmt.Errorf("planquery: resource change has no after")}
+	}
+	return &Attrs{raw: after}
+}
@@ -0,0 +1,80 @@
+package planquery
+
+import "testing"
+
+func Test_Plan_ByAddress(t *testing.T) {
+	raw := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{
+				"address": "aws_s3_bucket.this",
+				"type":    "aws_s3_bucket",
+				"change": map[string]interface{}{
+					"after": map[string]interface{}{"bucket": "acme-prod-logs"},
+				},
+			},
+		},
+	}
+	plan := &Plan{raw: raw, view: viewResourceChanges}
+
+	if err := plan.ByAddress("aws_s3_bucket.this").Err(); err != nil {
+		t.Fatalf("ByAddress(existing) Err() = %v, want nil", err)
+	}
+	if err := plan.ByAddress("aws_s3_bucket.missing").Err(); err == nil {
+		t.Fatal("ByAddress(missing).Err() = nil, want error")
+	}
+}
+
+func Test_Plan_OfType_ResourceSet(t *testing.T) {
+	raw := map[string]interface{}{
+		"resource_changes": []interface{}{
+			map[string]interface{}{"address": "aws_s3_bucket.a", "type": "aws_s3_bucket"},
+			map[string]interface{}{"address": "aws_s3_bucket.b", "type": "aws_s3_bucket"},
+			map[string]interface{}{"address": "aws_iam_role.oidc", "type": "aws_iam_role"},
+		},
+	}
+	plan := &Plan{raw: raw, view: viewResourceChanges}
+
+	buckets := plan.OfType("aws_s3_bucket")
+	if err := buckets.Err(); err != nil {
+		t.Fatalf("OfType(matching).Err() = %v, want nil", err)
+	}
+	if buckets.Len() != 2 {
+		t.Fatalf("OfType(aws_s3_bucket).Len() = %d, want 2", buckets.Len())
+	}
+
+	none := plan.OfType("aws_kms_key")
+	if err := none.Err(); err != nil {
+		t.Fatalf("OfType(no matches).Err() = %v, want nil", err)
+	}
+	if none.Len() != 0 {
+		t.Fatalf("OfType(no matches).Len() = %d, want 0", none.Len())
+	}
+	if err := none.First().Err(); err == nil {
+		t.Fatal("First() on an empty ResourceSet returned nil error")
+	}
+}
+
+func Test_Plan_resources_MissingOrMalformed(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  map[string]interface{}
+	}{
+		{name: "no resource_changes key", raw: map[string]interface{}{}},
+		{name: "resource_changes is not an array", raw: map[string]interface{}{"resource_changes": "oops"}},
+		{name: "resource_changes has a non-object element", raw: map[string]interface{}{
+			"resource_changes": []interface{}{"oops"},
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := &Plan{raw: tc.raw, view: viewResourceChanges}
+			if err := plan.OfType("aws_s3_bucket").Err(); err == nil {
+				t.Fatal("OfType().Err() = nil, want error")
+			}
+			if err := plan.ByAddress("anything").Err(); err == nil {
+				t.Fatal("ByAddress().Err() = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package drift detects out-of-band changes to a live environment by
+// diffing a `terraform plan -refresh-only` against the security-critical
+// attributes this repo cares about (PAB flags, versioning, SSE, bucket
+// policy, OIDC thumbprints, IAM trust policy).
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"reflect"
+)
+
+// SecurityCriticalAttrs lists, per resource type, the attributes drift
+// detection reports on. A plan may show other changes (tags, descriptions,
+// ...) that we don't treat as drift worth paging someone over.
+var SecurityCriticalAttrs = map[string][]string{
+	"aws_s3_bucket_public_access_block":                  {"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"},
+	"aws_s3_bucket_versioning":                           {"versioning_configuration"},
+	"aws_s3_bucket_server_side_encryption_configuration": {"rule"},
+	"aws_s3_bucket_policy":                               {"policy"},
+	"aws_iam_openid_connect_provider":                    {"thumbprint_list"},
+	"aws_iam_role":                                       {"assume_role_policy"},
+}
+
+// AttributeDrift is a single attribute that differs between the last
+// applied state and the current refresh.
+type AttributeDrift struct {
+	Attribute string
+	Before    interface{}
+	After     interface{}
+}
+
+// ResourceDrift is every security-critical attribute that drifted on one
+// resource.
+type ResourceDrift struct {
+	Address string
+	Type    string
+	Changes []AttributeDrift
+}
+
+// Report is the full drift-detection result for an environment.
+type Report struct {
+	Resources []ResourceDrift
+}
+
+// HasDrift reports whether any security-critical attribute drifted.
+func (r Report) HasDrift() bool {
+	return len(r.Resources) > 0
+}
+
+// Detect runs `terraform plan -detailed-exitcode -refresh-only` against
+// dir and reports any security-critical attribute that differs between
+// state and the live refresh. Callers are expected to have already run
+// `terraform init` with real credentials configured.
+func Detect(ctx context.Context, dir string) (*Report, error) {
+	planPath := "drift.tfplan"
+
+	planCmd := exec.CommandContext(ctx, "terraform", "-chdir="+dir, "plan",
+		"-detailed-exitcode", "-refresh-only", "-input=false", "-lock=false", "-out="+planPath)
+	out, err := planCmd.CombinedOutput()
+	if err != nil {
+		// exit code 2 means terraform found changes, which is the
+		// expected outcome when there's drift to report.
+		if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+			return nil, fmt.Errorf("drift: terraform plan -refresh-only failed: %w\n%s", err, out)
+		}
+	}
+
+	showOut, err := exec.CommandContext(ctx, "terraform", "-chdir="+dir, "show", "-json", planPath).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("drift: terraform show -json failed: %w\n%s", err, showOut)
+	}
+
+	var plan map[string]interface{}
+	if err := json.Unmarshal(showOut, &plan); err != nil {
+		return nil, fmt.Errorf("drift: parse plan: %w", err)
+	}
+
+	return buildReport(plan), nil
+}
+
+// buildReport walks plan["resource_drift"], the field terraform populates
+// with refresh-detected differences for a `-refresh-only` plan.
+// resource_changes is the wrong field here: it reflects proposed config
+// changes, and a `-refresh-only` plan proposes none, so resource_changes
+// is always empty for this use case.
+func buildReport(plan map[string]interface{}) *Report {
+	rcAny, _ := plan["resource_drift"].([]interface{})
+
+	report := &Report{}
+	for _, rAny := range rcAny {
+		rc, ok := rAny.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		typ, _ := rc["type"].(string)
+		attrs, tracked := SecurityCriticalAttrs[typ]
+		if !tracked {
+			continue
+		}
+
+		change, _ := rc["change"].(map[string]interface{})
+		before, _ := change["before"].(map[string]interface{})
+		after, _ := change["after"].(map[string]interface{})
+
+		var changes []AttributeDrift
+		for _, attr := range attrs {
+			b, a := before[attr], after[attr]
+			if !reflect.DeepEqual(b, a) {
+				changes = append(changes, AttributeDrift{Attribute: attr, Before: b, After: a})
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		address, _ := rc["address"].(string)
+		report.Resources = append(report.Resources, ResourceDrift{Address: address, Type: typ, Changes: changes})
+	}
+	return report
+}
@@ -0,0 +1,101 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_buildReport(t *testing.T) {
+	cases := []struct {
+		name string
+		plan map[string]interface{}
+		want []ResourceDrift
+	}{
+		{
+			name: "no resource_drift field",
+			plan: map[string]interface{}{},
+			want: nil,
+		},
+		{
+			name: "resource_changes is ignored, only resource_drift is read",
+			plan: map[string]interface{}{
+				"resource_changes": []interface{}{
+					map[string]interface{}{
+						"address": "aws_s3_bucket_public_access_block.this",
+						"type":    "aws_s3_bucket_public_access_block",
+						"change": map[string]interface{}{
+							"before": map[string]interface{}{"block_public_acls": true},
+							"after":  map[string]interface{}{"block_public_acls": false},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "drift in a tracked attribute is reported",
+			plan: map[string]interface{}{
+				"resource_drift": []interface{}{
+					map[string]interface{}{
+						"address": "aws_s3_bucket_public_access_block.this",
+						"type":    "aws_s3_bucket_public_access_block",
+						"change": map[string]interface{}{
+							"before": map[string]interface{}{"block_public_acls": true},
+							"after":  map[string]interface{}{"block_public_acls": false},
+						},
+					},
+				},
+			},
+			want: []ResourceDrift{
+				{
+					Address: "aws_s3_bucket_public_access_block.this",
+					Type:    "aws_s3_bucket_public_access_block",
+					Changes: []AttributeDrift{
+						{Attribute: "block_public_acls", Before: true, After: false},
+					},
+				},
+			},
+		},
+		{
+			name: "untracked resource type is ignored even with a diff",
+			plan: map[string]interface{}{
+				"resource_drift": []interface{}{
+					map[string]interface{}{
+						"address": "aws_s3_bucket.this",
+						"type":    "aws_s3_bucket",
+						"change": map[string]interface{}{
+							"before": map[string]interface{}{"tags": map[string]interface{}{"env": "dev"}},
+							"after":  map[string]interface{}{"tags": map[string]interface{}{"env": "prod"}},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "tracked type with identical before/after produces no drift",
+			plan: map[string]interface{}{
+				"resource_drift": []interface{}{
+					map[string]interface{}{
+						"address": "aws_iam_role.oidc",
+						"type":    "aws_iam_role",
+						"change": map[string]interface{}{
+							"before": map[string]interface{}{"assume_role_policy": "{}"},
+							"after":  map[string]interface{}{"assume_role_policy": "{}"},
+						},
+					},
+				},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildReport(tc.plan).Resources
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("buildReport() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
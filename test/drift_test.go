@@ -0,0 +1,30 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/KolaAina/GO-terraform-s3-devops-project/test/drift"
+)
+
+// Test_DriftCheck compares live AWS state for the dev environment against
+// what terraform expects and fails if any security-critical attribute has
+// drifted out of band (e.g. a console edit that loosened a public access
+// block). It needs real credentials against the dev account, so it's
+// skipped everywhere except the nightly drift-detection workflow.
+func Test_DriftCheck(t *testing.T) {
+	if os.Getenv("AWS_ACCESS_KEY_ID") == "" && os.Getenv("AWS_PROFILE") == "" {
+		t.Skip("Test_DriftCheck requires real AWS credentials (AWS_ACCESS_KEY_ID or AWS_PROFILE); skipping")
+	}
+
+	devDir := filepath.Join("..", "envs", "dev", "s3")
+	runTf(t, devDir, "init")
+
+	report, err := drift.Detect(context.Background(), devDir)
+	require.NoError(t, err, "drift detection failed")
+	require.False(t, report.HasDrift(), "security-critical drift detected: %+v", report.Resources)
+}
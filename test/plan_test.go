@@ -1,7 +1,9 @@
 package test
 
 import (
+	"context"
 	"encoding/json"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -9,6 +11,9 @@ import (
 
 	"github.com/gruntwork-io/terratest/modules/terraform"
 	"github.com/stretchr/testify/require"
+
+	"github.com/KolaAina/GO-terraform-s3-devops-project/internal/planquery"
+	"github.com/KolaAina/GO-terraform-s3-devops-project/test/planchecks"
 )
 
 func runTf(t *testing.T, dir string, args ...string) {
@@ -16,36 +21,90 @@ func runTf(t *testing.T, dir string, args ...string) {
 	terraform.RunTerraformCommand(t, &terraform.Options{TerraformDir: dir, NoColor: true}, args...)
 }
 
-// ---- Helpers to handle "object OR list-of-objects" in plan JSON ----
-func asMap(v interface{}) map[string]interface{} {
-	if v == nil {
+func toStringSlice(v interface{}) []string {
+	switch vv := v.(type) {
+	case string:
+		return []string{vv}
+	case []interface{}:
+		out := make([]string, 0, len(vv))
+		for _, x := range vv {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
 		return nil
 	}
-	if m, ok := v.(map[string]interface{}); ok {
-		return m
-	}
-	return nil
 }
-func firstMap(v interface{}) map[string]interface{} {
-	if m := asMap(v); m != nil {
-		return m
-	}
-	if arr, ok := v.([]interface{}); ok && len(arr) > 0 {
-		if m, ok := arr[0].(map[string]interface{}); ok {
-			return m
+
+func containsAny(list []string, items ...string) bool {
+	for _, l := range list {
+		for _, it := range items {
+			if l == it {
+				return true
+			}
 		}
 	}
-	return nil
+	return false
 }
-func getAfter(m map[string]interface{}) map[string]interface{} {
-	ch := asMap(m["change"])
-	if ch == nil {
-		return map[string]interface{}{}
-	}
-	if after := asMap(ch["after"]); after != nil {
-		return after
+
+// findByAddress returns the resource at the given terraform address, or
+// fails the test immediately if it's not found. Test_PlanChecks is its
+// only caller.
+func findByAddress(t *testing.T, plan *planquery.Plan, address string) *planquery.Resource {
+	t.Helper()
+	r := plan.ByAddress(address)
+	require.NoError(t, r.Err(), "resource %q not found in plan", address)
+	return r
+}
+
+// requireKMSKeyConfig asserts a CMK used for S3 encryption has rotation
+// enabled, a sane deletion window, and a key policy that scopes
+// kms:Decrypt/kms:GenerateDataKey to roleName rather than "*".
+// Test_PlanChecks is its only caller.
+func requireKMSKeyConfig(t *testing.T, after *planquery.Attrs, roleName string) {
+	t.Helper()
+
+	rotationEnabled, err := after.Bool("enable_key_rotation")
+	require.NoError(t, err, "enable_key_rotation missing/invalid")
+	require.True(t, rotationEnabled, "CMK must have key rotation enabled")
+
+	deletionWindow, err := after.Float64("deletion_window_in_days")
+	require.NoError(t, err, "deletion_window_in_days missing/invalid")
+	require.GreaterOrEqual(t, deletionWindow, float64(7), "deletion_window_in_days must be >= 7")
+
+	policyRaw, err := after.String("policy")
+	require.NoError(t, err, "KMS key policy missing")
+
+	var keyPolicy map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(policyRaw), &keyPolicy))
+
+	statements, ok := keyPolicy["Statement"].([]interface{})
+	require.True(t, ok, "key policy Statement missing/invalid")
+
+	foundDecryptStatement := false
+	for _, stmtAny := range statements {
+		stmt, ok := stmtAny.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if !containsAny(toStringSlice(stmt["Action"]), "kms:Decrypt", "kms:GenerateDataKey") {
+			continue
+		}
+		foundDecryptStatement = true
+
+		principal, ok := stmt["Principal"].(map[string]interface{})
+		require.True(t, ok, "decrypt/GenerateDataKey statement must scope Principal to a role, not \"*\"")
+
+		arns := toStringSlice(principal["AWS"])
+		require.NotEmpty(t, arns, "decrypt/GenerateDataKey statement must scope Principal to an AWS role ARN, not another principal type")
+		for _, arn := range arns {
+			require.NotEqual(t, "*", arn, "KMS key policy must not grant decrypt/GenerateDataKey to *")
+			require.Contains(t, arn, roleName, "KMS key policy should scope decrypt/GenerateDataKey to the OIDC role")
+		}
 	}
-	return map[string]interface{}{}
+	require.True(t, foundDecryptStatement, "expected a kms:Decrypt/kms:GenerateDataKey statement in the key policy")
 }
 
 func Test_PlanChecks(t *testing.T) {
@@ -63,77 +122,90 @@ func Test_PlanChecks(t *testing.T) {
 	out, err := exec.Command("terraform", "-chdir="+devDir, "show", "-json", "plan.tfplan").CombinedOutput()
 	require.NoError(t, err, "terraform show -json failed: %s", string(out))
 
-	var plan map[string]interface{}
-	require.NoError(t, json.Unmarshal(out, &plan))
-
-	rcAny := plan["resource_changes"]
-	require.NotNil(t, rcAny, "missing resource_changes")
-	rc := rcAny.([]interface{})
-
-	// Helpers
-	find := func(typ string) []map[string]interface{} {
-		var hits []map[string]interface{}
-		for _, r := range rc {
-			m := r.(map[string]interface{})
-			if m["type"] == typ {
-				hits = append(hits, m)
-			}
-		}
-		return hits
-	}
+	plan := planquery.Load(out)
+	require.NoError(t, plan.Err(), "failed to parse plan JSON")
 
 	// 1) Has S3 bucket and IAM role
-	require.Greater(t, len(find("aws_s3_bucket")), 0, "expected aws_s3_bucket")
-	require.Greater(t, len(find("aws_iam_role")), 0, "expected aws_iam_role")
+	require.Greater(t, plan.OfType("aws_s3_bucket").Len(), 0, "expected aws_s3_bucket")
+	roles := plan.OfType("aws_iam_role")
+	require.Greater(t, roles.Len(), 0, "expected aws_iam_role")
+	role := roles.First()
+	roleName, err := role.After().String("name")
+	require.NoError(t, err, "IAM role name missing/invalid")
+	roleAddress, err := role.Address()
+	require.NoError(t, err, "IAM role address missing")
 
 	// 2) Versioning Enabled
-	vers := find("aws_s3_bucket_versioning")
-	require.Greater(t, len(vers), 0, "expected bucket versioning")
-	afterVers := getAfter(vers[0])
-	vc := firstMap(afterVers["versioning_configuration"])
-	require.NotNil(t, vc, "versioning_configuration missing/invalid")
-	require.Equal(t, "Enabled", vc["status"], "versioning must be Enabled")
+	vers := plan.OfType("aws_s3_bucket_versioning")
+	require.Greater(t, vers.Len(), 0, "expected bucket versioning")
+	versioningConfig := vers.After().List("versioning_configuration")
+	require.NoError(t, versioningConfig.Err(), "versioning_configuration missing/invalid")
+	status, err := versioningConfig.First().String("status")
+	require.NoError(t, err, "versioning_configuration status missing/invalid")
+	require.Equal(t, "Enabled", status, "versioning must be Enabled")
 
 	// 3) SSE present (KMS preferred but allow AES256 if you chose that)
-	sse := find("aws_s3_bucket_server_side_encryption_configuration")
-	require.Greater(t, len(sse), 0, "expected SSE configuration")
-	afterSSE := getAfter(sse[0])
+	sse := plan.OfType("aws_s3_bucket_server_side_encryption_configuration")
+	require.Greater(t, sse.Len(), 0, "expected SSE configuration")
 
-	// "rule" is a LIST block in plan JSON
-	rule0 := firstMap(afterSSE["rule"])
-	require.NotNil(t, rule0, "SSE rule block missing/invalid")
+	sseRules := sse.After().List("rule")
+	require.NoError(t, sseRules.Err(), "SSE rule block missing/invalid")
+	rule0 := sseRules.First()
 
-	applied := firstMap(rule0["apply_server_side_encryption_by_default"])
-	require.NotNil(t, applied, "apply_server_side_encryption_by_default block missing/invalid")
+	appliedDefaults := rule0.List("apply_server_side_encryption_by_default")
+	require.NoError(t, appliedDefaults.Err(), "apply_server_side_encryption_by_default block missing/invalid")
+	applied := appliedDefaults.First()
 
 	// Check if KMS encryption is being used by looking for KMS resources
-	kmsKeys := find("aws_kms_key")
-	if len(kmsKeys) > 0 {
-		// KMS key is being created, so KMS encryption is enabled
-		require.Greater(t, len(kmsKeys), 0, "KMS key should be created when KMS encryption is enabled")
-		// When KMS key is specified, sse_algorithm defaults to "aws:kms" and may not appear in plan
+	kmsKeys := plan.OfType("aws_kms_key")
+	if kmsKeys.Len() > 0 {
+		// KMS key is being created, so KMS encryption is enabled.
+		// When KMS key is specified, sse_algorithm defaults to "aws:kms" and may not appear in plan.
+
+		// 3a) CMK rotation, deletion window, and key policy scoping
+		requireKMSKeyConfig(t, kmsKeys.After(), roleName)
+
+		// 3b) SSE rule must reference the CMK and enable the bucket-key
+		// optimization so every GET/PUT isn't a billed KMS API call.
+		masterKeyID, err := applied.String("kms_master_key_id")
+		require.NoError(t, err, "SSE rule must reference the CMK via kms_master_key_id")
+		require.NotEmpty(t, masterKeyID)
+
+		bucketKeyEnabled, err := rule0.Bool("bucket_key_enabled")
+		require.NoError(t, err, "bucket_key_enabled missing/invalid")
+		require.True(t, bucketKeyEnabled, "bucket_key_enabled must be true when using a CMK")
+
+		// 3c) A stable alias must exist so downstream resources can
+		// reference the key by name without re-planning on key rotation.
+		aliases := plan.OfType("aws_kms_alias")
+		require.Greater(t, aliases.Len(), 0, "expected an aws_kms_alias for the CMK")
+		aliasName, err := aliases.After().String("name")
+		require.NoError(t, err, "KMS alias name missing/invalid")
+		require.True(t, strings.HasPrefix(aliasName, "alias/"), "KMS alias name must start with alias/, got %q", aliasName)
 	} else {
 		// No KMS key, so should use AES256
-		alg, _ := applied["sse_algorithm"].(string)
+		alg, err := applied.String("sse_algorithm")
+		require.NoError(t, err, "sse_algorithm missing/invalid")
 		require.Equal(t, "AES256", alg, "should use AES256 when no KMS key specified")
 	}
 
 	// 4) Public access block flags
-	pab := find("aws_s3_bucket_public_access_block")
-	require.Greater(t, len(pab), 0, "expected public access block")
-	afterPAB := getAfter(pab[0])
-	require.Equal(t, true, afterPAB["block_public_acls"])
-	require.Equal(t, true, afterPAB["block_public_policy"])
-	require.Equal(t, true, afterPAB["ignore_public_acls"])
-	require.Equal(t, true, afterPAB["restrict_public_buckets"])
+	pab := plan.OfType("aws_s3_bucket_public_access_block")
+	require.Greater(t, pab.Len(), 0, "expected public access block")
+	pabAfter := pab.After()
+	for _, flag := range []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"} {
+		enabled, err := pabAfter.Bool(flag)
+		require.NoError(t, err, "%s missing/invalid", flag)
+		require.True(t, enabled, "%s must be true", flag)
+	}
 
 	// 5) OIDC trust policy - check for OIDC provider and IAM role
-	oidcProvider := find("aws_iam_openid_connect_provider")
-	require.Greater(t, len(oidcProvider), 0, "expected OIDC provider")
+	oidcProvider := plan.OfType("aws_iam_openid_connect_provider")
+	require.Greater(t, oidcProvider.Len(), 0, "expected OIDC provider")
+	oidcAfter := oidcProvider.After()
 
-	// Check OIDC provider configuration
-	afterOIDC := getAfter(oidcProvider[0])
-	url := afterOIDC["url"].(string)
+	url, err := oidcAfter.String("url")
+	require.NoError(t, err, "OIDC provider url missing/invalid")
 	// Accept both with and without https:// prefix as both are valid
 	// The correct URL should be https://token.actions.githubusercontent.com
 	// but existing providers might not have the https:// prefix
@@ -142,16 +214,60 @@ func Test_PlanChecks(t *testing.T) {
 	}
 	require.Equal(t, "https://token.actions.githubusercontent.com", url)
 
-	clientIDs := afterOIDC["client_id_list"].([]interface{})
+	clientIDs, err := oidcAfter.StringSlice("client_id_list")
+	require.NoError(t, err, "client_id_list missing/invalid")
 	require.Contains(t, clientIDs, "sts.amazonaws.com")
 
-	// Check IAM role exists
-	role := find("aws_iam_role")
-	require.Greater(t, len(role), 0, "expected IAM role")
-
-	// Since we can't easily validate the trust policy JSON in the plan (it's computed),
-	// we'll validate the presence of the OIDC provider and role, which indicates
-	// the OIDC trust policy is properly configured
-	roleName := getAfter(role[0])["name"].(string)
 	require.Contains(t, roleName, "oidc", "role should be for OIDC")
+
+	// 5a) assume_role_policy is computed in resource_changes until the role
+	// is created, so resolve it via planned_values instead, which holds the
+	// fully-resolved value even for a not-yet-applied plan.
+	plannedValues := plan.PlannedValues()
+	assumeRolePolicyRaw, err := findByAddress(t, plannedValues, roleAddress).After().String("assume_role_policy")
+	require.NoError(t, err, "assume_role_policy missing/invalid in planned_values")
+
+	var trustPolicy map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(assumeRolePolicyRaw), &trustPolicy))
+
+	trustStatements, ok := trustPolicy["Statement"].([]interface{})
+	require.True(t, ok, "assume_role_policy Statement missing/invalid")
+
+	foundOIDCTrustStatement := false
+	for _, stmtAny := range trustStatements {
+		stmt, ok := stmtAny.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if !containsAny(toStringSlice(stmt["Action"]), "sts:AssumeRoleWithWebIdentity") {
+			continue
+		}
+		foundOIDCTrustStatement = true
+
+		principal, ok := stmt["Principal"].(map[string]interface{})
+		require.True(t, ok, "AssumeRoleWithWebIdentity statement must scope Principal to the OIDC provider")
+		require.NotEmpty(t, toStringSlice(principal["Federated"]), "AssumeRoleWithWebIdentity statement missing Federated principal")
+	}
+	require.True(t, foundOIDCTrustStatement, "expected an sts:AssumeRoleWithWebIdentity trust statement for the OIDC role")
+
+	// 6) Evaluate the plan against the Rego policy set under /policies via
+	// planchecks.RunPlanChecks, the same entry point cmd/planguard uses in
+	// CI, so go test and the pipeline gate never disagree. The policies
+	// repeat the versioning, public access block, SSE algorithm and OIDC
+	// provider/role-name checks above (not the KMS key, alias or trust
+	// policy checks) and add guardrails the hand-coded checks miss
+	// (wildcard IAM policies, public ACLs, bucket naming); kept
+	// alongside the manual checks for now so a regression in the policy
+	// layer itself doesn't go unnoticed.
+	planJSONPath := filepath.Join(devDir, "plan.json")
+	require.NoError(t, os.WriteFile(planJSONPath, out, 0o644))
+
+	result, err := planchecks.RunPlanChecks(context.Background(), planchecks.PlanCheckConfig{
+		PlanPath:    planJSONPath,
+		PoliciesDir: filepath.Join("..", "policies"),
+	})
+	require.NoError(t, err, "policy evaluation failed")
+
+	failing := result.Failing(planchecks.SeverityDeny)
+	require.Empty(t, failing, "policy violations: %+v", failing)
 }
@@ -0,0 +1,142 @@
+// Package planchecks is the shared entry point for running the Rego policy
+// set against a terraform plan. Test_PlanChecks and the cmd/planguard CI
+// binary both call RunPlanChecks so `go test` and pipeline runs can never
+// disagree about what passes.
+package planchecks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/KolaAina/GO-terraform-s3-devops-project/test/policy"
+)
+
+// Severity is the gating level assigned to a violation, mirroring the
+// deny/warn distinction conftest and tfsec use.
+type Severity string
+
+const (
+	SeverityWarn Severity = "warn"
+	SeverityDeny Severity = "deny"
+)
+
+// ParseSeverity accepts the canonical "warn"/"deny" names as well as the
+// "low"/"high" aliases used by -fail-on on the CLI.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(s) {
+	case "warn", "low":
+		return SeverityWarn, nil
+	case "deny", "high":
+		return SeverityDeny, nil
+	default:
+		return "", fmt.Errorf("planchecks: unknown severity %q", s)
+	}
+}
+
+// rank orders severities so Failing can compare against a threshold.
+func (s Severity) rank() int {
+	if s == SeverityDeny {
+		return 1
+	}
+	return 0
+}
+
+// Violation is a single policy rule failure.
+type Violation struct {
+	Package  string
+	Severity Severity
+	Message  string
+}
+
+// PlanCheckConfig configures a policy run against a single terraform plan.
+type PlanCheckConfig struct {
+	// PlanPath is the path to a `terraform show -json` plan document.
+	PlanPath string
+	// PoliciesDir is the root directory of Rego policies to evaluate.
+	PoliciesDir string
+	// ReportFormat is "junit", "sarif", or "" to skip report generation.
+	ReportFormat string
+	// OutputPath is where the report is written when ReportFormat is set.
+	OutputPath string
+}
+
+// Result is the outcome of a policy run against one plan.
+type Result struct {
+	Violations []Violation
+}
+
+// Failing returns the violations at or above threshold.
+func (r Result) Failing(threshold Severity) []Violation {
+	var out []Violation
+	for _, v := range r.Violations {
+		if v.Severity.rank() >= threshold.rank() {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// RunPlanChecks loads the plan at cfg.PlanPath, evaluates it against
+// cfg.PoliciesDir, optionally writes a JUnit or SARIF report to
+// cfg.OutputPath, and returns every violation found.
+func RunPlanChecks(ctx context.Context, cfg PlanCheckConfig) (*Result, error) {
+	raw, err := os.ReadFile(cfg.PlanPath)
+	if err != nil {
+		return nil, fmt.Errorf("planchecks: read plan: %w", err)
+	}
+
+	var plan interface{}
+	if err := json.Unmarshal(raw, &plan); err != nil {
+		return nil, fmt.Errorf("planchecks: parse plan: %w", err)
+	}
+
+	violations, err := policy.EvaluateViolations(ctx, cfg.PoliciesDir, plan)
+	if err != nil {
+		return nil, fmt.Errorf("planchecks: evaluate policies: %w", err)
+	}
+
+	result := &Result{Violations: toViolations(violations)}
+
+	if cfg.ReportFormat != "" {
+		if err := writeReport(cfg, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func toViolations(pv []policy.Violation) []Violation {
+	out := make([]Violation, len(pv))
+	for i, v := range pv {
+		sev := SeverityDeny
+		if v.Severity == string(SeverityWarn) {
+			sev = SeverityWarn
+		}
+		out[i] = Violation{Package: v.Package, Severity: sev, Message: v.Message}
+	}
+	return out
+}
+
+func writeReport(cfg PlanCheckConfig, result *Result) error {
+	var body []byte
+	var err error
+	switch cfg.ReportFormat {
+	case "junit":
+		body, err = renderJUnit(result)
+	case "sarif":
+		body, err = renderSARIF(result, cfg.PlanPath)
+	default:
+		return fmt.Errorf("planchecks: unknown report format %q", cfg.ReportFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("planchecks: render %s report: %w", cfg.ReportFormat, err)
+	}
+	if err := os.WriteFile(cfg.OutputPath, body, 0o644); err != nil {
+		return fmt.Errorf("planchecks: write report: %w", err)
+	}
+	return nil
+}
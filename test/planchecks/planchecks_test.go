@@ -0,0 +1,28 @@
+package planchecks
+
+import "testing"
+
+func Test_Result_Failing(t *testing.T) {
+	result := Result{Violations: []Violation{
+		{Package: "s3", Severity: SeverityWarn, Message: "warn one"},
+		{Package: "iam", Severity: SeverityDeny, Message: "deny one"},
+	}}
+
+	cases := []struct {
+		name      string
+		threshold Severity
+		want      int
+	}{
+		{name: "warn threshold includes both severities", threshold: SeverityWarn, want: 2},
+		{name: "deny threshold excludes warn violations", threshold: SeverityDeny, want: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := result.Failing(tc.threshold)
+			if len(got) != tc.want {
+				t.Errorf("Failing(%v) returned %d violations, want %d", tc.threshold, len(got), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package planchecks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+type junitTestsuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Testcases []junitTestcase `xml:"testcase"`
+}
+
+type junitTestcase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnit reports one testcase per violation so results show up
+// individually in GitHub Actions' test summary. Only "deny" violations are
+// rendered as <failure> - that's the severity planguard actually gates on
+// (-fail-on deny), so a warn-only run must still read as a passing suite.
+// "warn" violations are reported as passing testcases carrying a
+// <system-out> note, so they're visible without contradicting the step's
+// outcome. A clean run still emits a single passing testcase so the suite
+// isn't reported as empty.
+func renderJUnit(result *Result) ([]byte, error) {
+	suite := junitTestsuite{Name: "planchecks"}
+
+	if len(result.Violations) == 0 {
+		suite.Tests = 1
+		suite.Testcases = append(suite.Testcases, junitTestcase{Name: "policies", Classname: "planchecks"})
+	}
+	for i, v := range result.Violations {
+		suite.Tests++
+		tc := junitTestcase{
+			Name:      fmt.Sprintf("%s#%d", v.Package, i),
+			Classname: "planchecks." + v.Package,
+		}
+		if v.Severity == "deny" {
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Message: string(v.Severity),
+				Text:    v.Message,
+			}
+		} else {
+			tc.SystemOut = fmt.Sprintf("%s: %s", v.Severity, v.Message)
+		}
+		suite.Testcases = append(suite.Testcases, tc)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
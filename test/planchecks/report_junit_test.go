@@ -0,0 +1,85 @@
+package planchecks
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func Test_renderJUnit(t *testing.T) {
+	cases := []struct {
+		name           string
+		violations     []Violation
+		wantFailures   int
+		wantTests      int
+		wantSystemOuts int
+	}{
+		{
+			name:      "no violations still emits a passing testcase",
+			wantTests: 1,
+		},
+		{
+			name: "deny violation renders as a failure",
+			violations: []Violation{
+				{Package: "s3", Severity: SeverityDeny, Message: "bucket must not be public"},
+			},
+			wantTests:    1,
+			wantFailures: 1,
+		},
+		{
+			name: "warn violation renders as a passing testcase with a system-out note",
+			violations: []Violation{
+				{Package: "s3", Severity: SeverityWarn, Message: "bucket name must carry the org prefix"},
+			},
+			wantTests:      1,
+			wantFailures:   0,
+			wantSystemOuts: 1,
+		},
+		{
+			name: "mixed severities only count deny toward failures",
+			violations: []Violation{
+				{Package: "s3", Severity: SeverityWarn, Message: "warn one"},
+				{Package: "iam", Severity: SeverityDeny, Message: "deny one"},
+			},
+			wantTests:      2,
+			wantFailures:   1,
+			wantSystemOuts: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := renderJUnit(&Result{Violations: tc.violations})
+			if err != nil {
+				t.Fatalf("renderJUnit() error = %v", err)
+			}
+
+			var suite junitTestsuite
+			if err := xml.Unmarshal(body, &suite); err != nil {
+				t.Fatalf("unmarshal rendered JUnit: %v", err)
+			}
+
+			if suite.Tests != tc.wantTests {
+				t.Errorf("Tests = %d, want %d", suite.Tests, tc.wantTests)
+			}
+			if suite.Failures != tc.wantFailures {
+				t.Errorf("Failures = %d, want %d", suite.Failures, tc.wantFailures)
+			}
+
+			gotFailures, gotSystemOuts := 0, 0
+			for _, tcase := range suite.Testcases {
+				if tcase.Failure != nil {
+					gotFailures++
+				}
+				if tcase.SystemOut != "" {
+					gotSystemOuts++
+				}
+			}
+			if gotFailures != tc.wantFailures {
+				t.Errorf("testcases with <failure> = %d, want %d", gotFailures, tc.wantFailures)
+			}
+			if gotSystemOuts != tc.wantSystemOuts {
+				t.Errorf("testcases with <system-out> = %d, want %d", gotSystemOuts, tc.wantSystemOuts)
+			}
+		})
+	}
+}
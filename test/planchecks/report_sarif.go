@@ -0,0 +1,82 @@
+package planchecks
+
+import "encoding/json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// renderSARIF emits SARIF 2.1.0 so violations surface in GitHub's Security
+// tab the same way checkov/tfsec results do. Every result carries a
+// location pointing at the plan file that was evaluated - OPA's deny/warn
+// rules don't report a line within it, but GitHub won't anchor a finding to
+// the PR diff without at least an artifactLocation.
+func renderSARIF(result *Result, planPath string) ([]byte, error) {
+	run := sarifRun{
+		Tool:    sarifTool{Driver: sarifDriver{Name: "planguard", Version: "0.1.0"}},
+		Results: []sarifResult{},
+	}
+	for _, v := range result.Violations {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:  v.Package,
+			Level:   sarifLevel(v.Severity),
+			Message: sarifMessage{Text: v.Message},
+			Locations: []sarifLocation{
+				{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: planPath}}},
+			},
+		})
+	}
+
+	log := sarifLog{
+		Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+func sarifLevel(s Severity) string {
+	if s == SeverityDeny {
+		return "error"
+	}
+	return "warning"
+}
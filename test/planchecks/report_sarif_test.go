@@ -0,0 +1,67 @@
+package planchecks
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test_renderSARIF(t *testing.T) {
+	cases := []struct {
+		name       string
+		violations []Violation
+		planPath   string
+		wantLevel  string
+	}{
+		{
+			name:      "no violations still produces a valid empty run",
+			planPath:  "envs/dev/s3/plan.json",
+			wantLevel: "",
+		},
+		{
+			name: "deny violation is reported at error level",
+			violations: []Violation{
+				{Package: "s3", Severity: SeverityDeny, Message: "bucket must not be public"},
+			},
+			planPath:  "envs/dev/s3/plan.json",
+			wantLevel: "error",
+		},
+		{
+			name: "warn violation is reported at warning level",
+			violations: []Violation{
+				{Package: "s3", Severity: SeverityWarn, Message: "bucket name must carry the org prefix"},
+			},
+			planPath:  "envs/dev/s3/plan.json",
+			wantLevel: "warning",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := renderSARIF(&Result{Violations: tc.violations}, tc.planPath)
+			if err != nil {
+				t.Fatalf("renderSARIF() error = %v", err)
+			}
+
+			var log sarifLog
+			if err := json.Unmarshal(body, &log); err != nil {
+				t.Fatalf("unmarshal rendered SARIF: %v", err)
+			}
+
+			if len(log.Runs) != 1 {
+				t.Fatalf("Runs = %d, want 1", len(log.Runs))
+			}
+			results := log.Runs[0].Results
+			if len(results) != len(tc.violations) {
+				t.Fatalf("Results = %d, want %d", len(results), len(tc.violations))
+			}
+			for _, r := range results {
+				if r.Level != tc.wantLevel {
+					t.Errorf("Level = %q, want %q", r.Level, tc.wantLevel)
+				}
+				if r.Locations[0].PhysicalLocation.ArtifactLocation.URI != tc.planPath {
+					t.Errorf("Locations[0] URI = %q, want %q", r.Locations[0].PhysicalLocation.ArtifactLocation.URI, tc.planPath)
+				}
+			}
+		})
+	}
+}
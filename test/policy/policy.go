@@ -0,0 +1,103 @@
+// Package policy evaluates a terraform plan (as produced by `terraform show
+// -json`) against the Rego rule set under /policies, replacing the ad-hoc
+// map traversal the plan tests used to do by hand.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// orgBucketPrefixEnvVar names the env var that configures the org's bucket
+// naming prefix (see policies/s3/bucket_naming.rego). There's no repo-wide
+// default: unset it, and that rule is a no-op.
+const orgBucketPrefixEnvVar = "ORG_BUCKET_PREFIX"
+
+// Violation is a single rule failure surfaced by a policy package, tagged
+// with the rule set (deny or warn) it came from so callers can gate on
+// severity.
+type Violation struct {
+	Package  string
+	Severity string // "deny" or "warn"
+	Message  string
+}
+
+// ruleSets are the rule names collected from each loaded package, in the
+// severity they represent. "deny" rules always fail a plan; "warn" rules
+// are collected for visibility but don't fail by default.
+var ruleSets = map[string]string{
+	"deny": "deny",
+	"warn": "warn",
+}
+
+// EvaluateViolations loads every *.rego file under policiesDir and runs
+// their deny/warn rules against input, returning every violation found. A
+// nil/empty result means the plan passed every loaded policy.
+func EvaluateViolations(ctx context.Context, policiesDir string, input interface{}) ([]Violation, error) {
+	r := rego.New(
+		rego.Query("data"),
+		rego.Load([]string{policiesDir}, nil),
+		rego.Input(withOrgPrefix(input)),
+	)
+
+	rs, err := r.Eval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("policy: eval failed: %w", err)
+	}
+	if len(rs) == 0 || len(rs[0].Expressions) == 0 {
+		return nil, nil
+	}
+
+	data, ok := rs[0].Expressions[0].Value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("policy: unexpected result shape %T", rs[0].Expressions[0].Value)
+	}
+
+	var violations []Violation
+	for pkgName, pkgAny := range data {
+		pkg, ok := pkgAny.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for ruleName, severity := range ruleSets {
+			ruleAny, ok := pkg[ruleName]
+			if !ok {
+				continue
+			}
+			ruleSet, ok := ruleAny.([]interface{})
+			if !ok {
+				continue
+			}
+			for _, m := range ruleSet {
+				s, ok := m.(string)
+				if !ok {
+					continue
+				}
+				violations = append(violations, Violation{Package: pkgName, Severity: severity, Message: s})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// withOrgPrefix copies input and adds org_prefix from ORG_BUCKET_PREFIX, if
+// set, without mutating the caller's plan map.
+func withOrgPrefix(input interface{}) interface{} {
+	prefix := os.Getenv(orgBucketPrefixEnvVar)
+	if prefix == "" {
+		return input
+	}
+	m, ok := input.(map[string]interface{})
+	if !ok {
+		return input
+	}
+	merged := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		merged[k] = v
+	}
+	merged["org_prefix"] = prefix
+	return merged
+}